@@ -2,39 +2,57 @@ package chainbridge
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
-	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
-	"github.com/fxamacker/cbor/v2"
 	"github.com/lesismal/nbio"
+
+	"github.com/0KnowledgeNetwork/appchain-agent/clients/go/chainbridge/log"
 )
 
 type ChainBridge struct {
-	cmd          *exec.Cmd
-	socketFile   string
-	client       *nbio.Engine
-	conn         *nbio.Conn
-	responses    sync.Map
-	idCounter    int
-	errorHandler func(error)
-	logHandler   func(string)
-	dialRetries  int
-	dialTimeout  time.Duration
-	cmdTimeout   time.Duration
-	reconnect    bool
-	isConnected  bool
+	cmd              *exec.Cmd
+	cmdPath          string
+	cmdArgs          []string
+	socketFile       string
+	client           *nbio.Engine
+	conn             *nbio.Conn
+	responses        sync.Map
+	idCounter        int
+	sink             log.Sink
+	codec            Codec
+	negotiateChan    chan CommandResponse
+	negotiateTimeout time.Duration
+	readBuf          []byte
+	maxFrameSize     int
+	dialRetries      int
+	dialTimeout      time.Duration
+	cmdTimeout       time.Duration
+	reconnect        bool
+	isConnected      bool
+
+	// subprocess supervision (see supervisor.go)
+	exitHandler    func(pid int, ws syscall.WaitStatus)
+	restartPolicy  *RestartPolicy
+	restartCount   int
+	supervisorStop chan struct{}
+	stopping       bool
 
 	idCounterMu   sync.Mutex
 	isConnectedMu sync.Mutex
 	reconnectMu   sync.Mutex
+	restartMu     sync.Mutex
+	stoppingMu    sync.Mutex
+	writeMu       sync.Mutex
+	cmdMu         sync.Mutex
 }
 
 type CommandRequest struct {
@@ -49,6 +67,21 @@ type CommandResponse struct {
 	Error  string      `cbor:"error,omitempty"`
 	ID     int         `cbor:"id,omitempty"`
 	TX     string      `cbor:"tx,omitempty"`
+	// Final marks the last frame of a CommandStream response. Unary
+	// Command/CommandContext responses ignore it: the first (and only)
+	// frame they receive always completes the request.
+	Final bool `cbor:"final,omitempty"`
+}
+
+// CommandResponseChunk is one frame of a CommandStream response.
+type CommandResponseChunk = CommandResponse
+
+// pendingRequest is the responses map's value type: the channel a reply is
+// delivered on, and whether the request is a CommandStream (in which case
+// the map entry survives until a Final chunk or ctx cancellation).
+type pendingRequest struct {
+	ch     chan CommandResponse
+	stream bool
 }
 
 type Network struct {
@@ -99,47 +132,65 @@ func NewChainBridge(socketFileOrCommandName string, commandArgs ...string) *Chai
 	}
 
 	return &ChainBridge{
-		cmd:         cmd,
-		socketFile:  socketFileOrCommandName,
-		idCounter:   0,
-		dialRetries: 15,
-		dialTimeout: 10 * time.Second,
-		cmdTimeout:  50 * time.Second,
-		reconnect:   true,
-		isConnected: false,
+		cmd:              cmd,
+		cmdPath:          socketFileOrCommandName,
+		cmdArgs:          commandArgs,
+		socketFile:       socketFileOrCommandName,
+		idCounter:        0,
+		codec:            CBORCodec{},
+		negotiateChan:    make(chan CommandResponse, 1),
+		negotiateTimeout: 2 * time.Second,
+		maxFrameSize:     defaultMaxFrameSize,
+		dialRetries:      15,
+		dialTimeout:      10 * time.Second,
+		cmdTimeout:       50 * time.Second,
+		reconnect:        true,
+		isConnected:      false,
 	}
 }
 
-// Set a custom error handler to be called when an error occurs.
-func (c *ChainBridge) SetErrorHandler(handler func(error)) {
-	c.errorHandler = handler
+// getCmd and setCmd synchronize access to c.cmd: restartChild reassigns it
+// from the supervisor goroutine while Stop, reapChildren, and launchCommand
+// read it from others.
+func (c *ChainBridge) getCmd() *exec.Cmd {
+	c.cmdMu.Lock()
+	defer c.cmdMu.Unlock()
+	return c.cmd
 }
 
-// Set a custom log handler to be called for non-error logs.
-func (c *ChainBridge) SetLogHandler(handler func(string)) {
-	c.logHandler = handler
+func (c *ChainBridge) setCmd(cmd *exec.Cmd) {
+	c.cmdMu.Lock()
+	c.cmd = cmd
+	c.cmdMu.Unlock()
 }
 
-func (c *ChainBridge) handleError(err error) {
-	if c.errorHandler != nil {
-		c.errorHandler(err)
-	}
+// getSocketFile and setSocketFile synchronize access to c.socketFile for the
+// same reason as getCmd/setCmd: restartChild clears and repopulates it from
+// the supervisor goroutine while Stop and connectToSocket read it elsewhere.
+func (c *ChainBridge) getSocketFile() string {
+	c.cmdMu.Lock()
+	defer c.cmdMu.Unlock()
+	return c.socketFile
 }
 
-func (c *ChainBridge) log(message string) {
-	if c.logHandler != nil {
-		c.logHandler(message)
-	}
+func (c *ChainBridge) setSocketFile(path string) {
+	c.cmdMu.Lock()
+	c.socketFile = path
+	c.cmdMu.Unlock()
 }
 
 func (c *ChainBridge) connectToSocket() error {
 	for i := 0; i < c.dialRetries; i++ {
-		c.log(fmt.Sprintf("Attempting to connect to socket: %s (attempt %d/%d)", c.socketFile, i+1, c.dialRetries))
-		conn, err := nbio.DialTimeout("unix", c.socketFile, c.dialTimeout)
+		c.logEvent(log.LevelInfo, "connect", log.F("attempt", i+1), log.F("of", c.dialRetries))
+		conn, err := nbio.DialTimeout("unix", c.getSocketFile(), c.dialTimeout)
 		if err == nil {
 			c.conn, err = c.client.AddConn(conn)
 			if err == nil {
-				c.log("Successfully connected to socket.")
+				c.readBuf = nil
+				err = c.negotiateWireFormat()
+			}
+			if err == nil {
+				c.logEvent(log.LevelInfo, "connect", log.F("status", "connected"), log.F("codec", c.codec.Name()))
 				c.isConnectedMu.Lock()
 				c.isConnected = true
 				c.isConnectedMu.Unlock()
@@ -149,7 +200,7 @@ func (c *ChainBridge) connectToSocket() error {
 		if !c.reconnect {
 			return nil
 		}
-		c.log(fmt.Sprintf("Failed to connect to socket: %v", err))
+		c.logEvent(log.LevelWarn, "connect", log.F("err", err))
 		time.Sleep(2 * time.Second)
 	}
 	return fmt.Errorf("Failed to connect after %d attempts", c.dialRetries)
@@ -159,39 +210,12 @@ func (c *ChainBridge) connectToSocket() error {
 // - connecting to the existing socket path or
 // - executing the provided command, then connecting to the socket path printed in its stdout.
 func (c *ChainBridge) Start() error {
-	c.log("Starting...")
-
-	if c.cmd != nil {
-		stdout, err := c.cmd.StdoutPipe()
-		if err != nil {
-			return err
-		}
-		stderr, err := c.cmd.StderrPipe()
-		if err != nil {
-			return err
-		}
+	c.logEvent(log.LevelInfo, "start")
 
-		if err := c.cmd.Start(); err != nil {
+	if c.getCmd() != nil {
+		if err := c.launchCommand(); err != nil {
 			return err
 		}
-
-		// Read the socket location from stdout
-		outScanner := bufio.NewScanner(io.MultiReader(stdout, stderr))
-		for outScanner.Scan() {
-			line := outScanner.Text()
-			const prefix = "UNIX_SOCKET_PATH="
-			if strings.HasPrefix(line, prefix) {
-				c.socketFile = strings.TrimPrefix(line, prefix)
-				break
-			}
-		}
-		if err := outScanner.Err(); err != nil {
-			return err
-		}
-
-		if c.socketFile == "" {
-			return fmt.Errorf("socket path not found in output")
-		}
 	}
 
 	c.client = nbio.NewEngine(nbio.Config{})
@@ -206,20 +230,119 @@ func (c *ChainBridge) Start() error {
 	c.reconnect = true
 	c.reconnectMu.Unlock()
 
+	if c.getCmd() != nil {
+		c.supervisorStop = make(chan struct{})
+		c.superviseChild()
+	}
+
 	return c.connectToSocket()
 }
 
+// launchCommand starts c.cmd and reads the UNIX_SOCKET_PATH= line it prints
+// on stdout/stderr, populating c.socketFile. Used by both Start and the
+// supervisor's restart path, which replaces c.cmd with a fresh exec.Cmd
+// before calling this again.
+func (c *ChainBridge) launchCommand() error {
+	cmd := c.getCmd()
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// Read the socket location from stdout
+	var socketFile string
+	outScanner := bufio.NewScanner(io.MultiReader(stdout, stderr))
+	for outScanner.Scan() {
+		line := outScanner.Text()
+		const prefix = "UNIX_SOCKET_PATH="
+		if strings.HasPrefix(line, prefix) {
+			socketFile = strings.TrimPrefix(line, prefix)
+			break
+		}
+	}
+	if err := outScanner.Err(); err != nil {
+		return err
+	}
+
+	if socketFile == "" {
+		return fmt.Errorf("socket path not found in output")
+	}
+	c.setSocketFile(socketFile)
+
+	return nil
+}
+
+// onData accumulates incoming bytes into c.readBuf and dispatches each
+// complete, length-prefixed frame as it becomes available: a single nbio
+// callback may deliver a partial frame (split across reads) or several
+// frames coalesced into one read, so frames can't be decoded straight off
+// the raw callback data. See framing.go for the frame format.
 func (c *ChainBridge) onData(conn *nbio.Conn, data []byte) {
+	c.readBuf = append(c.readBuf, data...)
+
+	for {
+		frame, ok, err := popFrame(c.readBuf, c.maxFrameSize)
+		if err != nil {
+			c.handleError(err)
+			c.readBuf = nil
+			return
+		}
+		if !ok {
+			return
+		}
+		c.readBuf = c.readBuf[frameHeaderSize+len(frame):]
+		c.dispatchFrame(frame)
+	}
+}
+
+func (c *ChainBridge) dispatchFrame(frame []byte) {
 	var response CommandResponse
-	if err := cbor.Unmarshal(data, &response); err != nil {
-		c.handleError(fmt.Errorf("CBOR Unmarshal error: %w", err))
+	if err := c.codec.Unmarshal(frame, &response); err != nil {
+		c.handleError(fmt.Errorf("%s unmarshal error (possible wire format mismatch): %w", c.codec.Name(), err))
+		// a pending wire format negotiation treats an undecodable reply as a
+		// mismatch rather than waiting out negotiateTimeout
+		select {
+		case c.negotiateChan <- CommandResponse{ID: negotiateID, Error: err.Error()}:
+		default:
+		}
+		return
+	}
+
+	c.logEvent(log.LevelDebug, "recv", log.F("reqID", response.ID))
+
+	// reqID 0 is reserved for the connect-time wire format negotiation
+	if response.ID == negotiateID {
+		select {
+		case c.negotiateChan <- response:
+		default:
+		}
 		return
 	}
 
-	// Dispatch the response to the correct channel
-	if ch, ok := c.responses.Load(response.ID); ok {
-		ch.(chan CommandResponse) <- response
-		c.responses.Delete(response.ID)
+	entry, ok := c.responses.Load(response.ID)
+	if !ok {
+		return
+	}
+	pr := entry.(*pendingRequest)
+
+	select {
+	case pr.ch <- response:
+	default:
+	}
+
+	if !pr.stream || response.Final {
+		if _, deleted := c.responses.LoadAndDelete(response.ID); deleted && pr.stream {
+			close(pr.ch)
+		}
 	}
 }
 
@@ -228,7 +351,9 @@ func (c *ChainBridge) onClose(conn *nbio.Conn, err error) {
 	c.isConnected = false
 	c.isConnectedMu.Unlock()
 
-	c.log("Connection closed")
+	c.logEvent(log.LevelInfo, "close", log.F("err", err))
+
+	c.failPendingRequests(fmt.Errorf("connection closed: %w", err))
 
 	if c.reconnect {
 		if err := c.connectToSocket(); err != nil {
@@ -237,29 +362,64 @@ func (c *ChainBridge) onClose(conn *nbio.Conn, err error) {
 	}
 }
 
+// failPendingRequests delivers reason to every in-flight Command/CommandStream
+// caller and clears the responses map, so a dropped connection fails them
+// immediately instead of leaving them blocked until cmdTimeout.
+func (c *ChainBridge) failPendingRequests(reason error) {
+	c.responses.Range(func(key, value any) bool {
+		reqID := key.(int)
+		if _, ok := c.responses.LoadAndDelete(reqID); !ok {
+			return true
+		}
+		pr := value.(*pendingRequest)
+		select {
+		case pr.ch <- CommandResponse{ID: reqID, Error: reason.Error()}:
+		default:
+		}
+		close(pr.ch)
+		return true
+	})
+}
+
 func (c *ChainBridge) Stop() error {
-	c.log("Stopping...")
+	c.logEvent(log.LevelInfo, "close", log.F("status", "stopping"))
+
+	c.stoppingMu.Lock()
+	c.stopping = true
+	c.stoppingMu.Unlock()
 
 	c.reconnectMu.Lock()
 	c.reconnect = false
 	c.reconnectMu.Unlock()
 
+	if c.supervisorStop != nil {
+		close(c.supervisorStop)
+	}
+
 	if c.client != nil {
 		c.client.Stop()
 	}
 
-	if c.cmd != nil {
-		if err := c.cmd.Process.Signal(syscall.SIGTERM); err != nil {
+	if cmd := c.getCmd(); cmd != nil {
+		if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
 			return err
 		}
 		// the agent process should cleanup, but make sure
-		os.Remove(c.socketFile)
+		os.Remove(c.getSocketFile())
 	}
 
 	return nil
 }
 
 func (c *ChainBridge) Command(command string, payload []byte) (CommandResponse, error) {
+	return c.CommandContext(context.Background(), command, payload)
+}
+
+// CommandContext is like Command but takes a context.Context, allowing the
+// caller to cancel the request or override the bridge's default cmdTimeout
+// (via context.WithTimeout/WithDeadline) on a per-call basis. Cancellation
+// or timeout cleans up the pending entry in the responses map.
+func (c *ChainBridge) CommandContext(ctx context.Context, command string, payload []byte) (CommandResponse, error) {
 	var response CommandResponse
 
 	if !c.isConnected {
@@ -278,80 +438,127 @@ func (c *ChainBridge) Command(command string, payload []byte) (CommandResponse,
 		ID:      reqID,
 	}
 
-	reqData, err := cbor.Marshal(req)
+	reqData, err := c.codec.Marshal(req)
 	if err != nil {
-		return response, fmt.Errorf("CBOR Marshal error: %w", err)
+		return response, fmt.Errorf("%s marshal error: %w", c.codec.Name(), err)
 	}
 
 	// Create a response channel and store it in the map
 	responseChan := make(chan CommandResponse, 1)
-	c.responses.Store(req.ID, responseChan)
+	c.responses.Store(req.ID, &pendingRequest{ch: responseChan})
 
 	// Send the request
-	_, err = c.conn.Write(reqData)
+	start := time.Now()
+	err = c.writeFrame(reqData)
 	if err != nil {
+		c.responses.Delete(req.ID)
 		return response, fmt.Errorf("Write error: %w", err)
 	}
+	c.logEvent(log.LevelDebug, "send", log.F("reqID", req.ID), log.F("command", req.Command))
+
+	// a context deadline tighter than cmdTimeout overrides it for this call
+	timeout := c.cmdTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		if d := time.Until(deadline); d < timeout {
+			timeout = d
+		}
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
 
 	// Wait for the response with a timeout
 	select {
 	case response = <-responseChan:
+		c.logEvent(log.LevelDebug, "recv", log.F("reqID", req.ID), log.F("command", req.Command),
+			log.F("duration_ms", time.Since(start).Milliseconds()))
 		return response, nil
-	case <-time.After(c.cmdTimeout):
+	case <-ctx.Done():
 		c.responses.Delete(req.ID)
+		return response, ctx.Err()
+	case <-timer.C:
+		c.responses.Delete(req.ID)
+		c.logEvent(log.LevelWarn, "timeout", log.F("reqID", req.ID), log.F("command", req.Command),
+			log.F("duration_ms", time.Since(start).Milliseconds()))
 		return response, fmt.Errorf("Timeout waiting for response to request ID=%d: %s", req.ID, req.Command)
 	}
 }
 
-func (c *ChainBridge) GetDataBool(response CommandResponse) (bool, error) {
-	if response.Data == nil {
-		return false, ErrNoData
+// CommandStream is like CommandContext, but for commands whose response is
+// split across multiple frames (e.g. large payloads that would otherwise
+// exceed maxFrameSize): it returns a channel delivering each CommandResponseChunk
+// as it arrives, closed after the chunk with Final set true or when ctx is
+// done, whichever comes first.
+func (c *ChainBridge) CommandStream(ctx context.Context, command string, payload []byte) (<-chan CommandResponseChunk, error) {
+	if !c.isConnected {
+		return nil, fmt.Errorf("Not connected")
 	}
 
-	b, ok := response.Data.(bool)
-	if !ok {
-		return false, fmt.Errorf("unexpected data type: %T, expected bool", response.Data)
+	c.idCounterMu.Lock()
+	c.idCounter++
+	reqID := c.idCounter
+	c.idCounterMu.Unlock()
+
+	req := CommandRequest{
+		Command: command,
+		Payload: payload,
+		ID:      reqID,
+	}
+
+	reqData, err := c.codec.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s marshal error: %w", c.codec.Name(), err)
 	}
 
-	return b, nil
+	responseChan := make(chan CommandResponse, 8)
+	c.responses.Store(req.ID, &pendingRequest{ch: responseChan, stream: true})
+
+	if err := c.writeFrame(reqData); err != nil {
+		c.responses.Delete(req.ID)
+		return nil, fmt.Errorf("Write error: %w", err)
+	}
+	c.logEvent(log.LevelDebug, "send", log.F("reqID", req.ID), log.F("command", req.Command))
+
+	go func() {
+		<-ctx.Done()
+		if _, deleted := c.responses.LoadAndDelete(req.ID); deleted {
+			close(responseChan)
+		}
+	}()
+
+	return responseChan, nil
 }
 
-func (c *ChainBridge) GetDataBytes(response CommandResponse) ([]byte, error) {
+// GetDataBool extracts a bool result from response.Data. How that's
+// represented depends on the active Codec, so the decoding is delegated to
+// c.codec rather than hard-coded here (see GetDataBytes).
+func (c *ChainBridge) GetDataBool(response CommandResponse) (bool, error) {
 	if response.Data == nil {
-		return nil, ErrNoData
+		return false, ErrNoData
 	}
 
-	// Check if the data is a CBOR tag
-	tag, ok := response.Data.(cbor.Tag)
-	if !ok {
-		return nil, fmt.Errorf("unexpected data type: %T, expected cbor.Tag", response.Data)
-	}
+	return c.codec.DecodeBool(response.Data)
+}
 
-	// Ensure the content is of type []byte
-	bytes, ok := tag.Content.([]byte)
-	if !ok {
-		return nil, fmt.Errorf("unexpected tag content type: %T, number: %d, expected []byte", tag.Content, tag.Number)
+// GetDataBytes extracts a []byte result from response.Data. How that's
+// represented depends on the active Codec (e.g. a CBOR tag vs. raw bytes),
+// so the unwrapping is delegated to c.codec rather than hard-coded here.
+func (c *ChainBridge) GetDataBytes(response CommandResponse) ([]byte, error) {
+	if response.Data == nil {
+		return nil, ErrNoData
 	}
 
-	return bytes, nil
+	return c.codec.DecodeBytes(response.Data)
 }
 
+// GetDataUInt extracts a uint64 result from response.Data. How that's
+// represented depends on the active Codec, so the decoding is delegated to
+// c.codec rather than hard-coded here (see GetDataBytes).
 func (c *ChainBridge) GetDataUInt(response CommandResponse) (uint64, error) {
 	if response.Data == nil {
 		return 0, ErrNoData
 	}
 
-	str, ok := response.Data.(string)
-	if !ok {
-		return 0, fmt.Errorf("unexpected data type: %T, expected string", response.Data)
-	}
-
-	num, err := strconv.ParseUint(str, 10, 64)
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse uint64: %w", err)
-	}
-
-	return num, nil
+	return c.codec.DecodeUint(response.Data)
 }
 
 func (c *ChainBridge) DataUnmarshal(response CommandResponse, v interface{}) error {
@@ -364,9 +571,8 @@ func (c *ChainBridge) DataUnmarshal(response CommandResponse, v interface{}) err
 		return fmt.Errorf("unexpected data type: %T, expected []byte", response.Data)
 	}
 
-	err := cbor.Unmarshal(bytes, v)
-	if err != nil {
-		return fmt.Errorf("CBOR Error: %v", err)
+	if err := c.codec.Unmarshal(bytes, v); err != nil {
+		return fmt.Errorf("%s unmarshal error: %v", c.codec.Name(), err)
 	}
 
 	return nil
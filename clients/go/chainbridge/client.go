@@ -0,0 +1,212 @@
+package chainbridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Client is a typed wrapper around a *ChainBridge. Where ChainBridge deals in
+// raw command strings and CommandResponse.Data, Client gives each appchain
+// command a Go method with concrete argument and return types, so payload
+// encoding and response decoding only need to be written once per command.
+type Client struct {
+	bridge *ChainBridge
+
+	// watch.go: subscription state for WatchEpochs/WatchMixDescriptors/WatchDocument
+	watchMu    sync.Mutex
+	epochWatch *epochWatch
+	mixWatches map[uint64]*mixDescriptorWatch
+	docWatches map[uint64]*documentWatch
+}
+
+// NewClient wraps an already-constructed ChainBridge in a typed Client.
+// The bridge must be started (and connected) before the Client is used.
+func NewClient(bridge *ChainBridge) *Client {
+	return &Client{bridge: bridge}
+}
+
+// decoder turns a successful CommandResponse into the Go value a Client
+// method should return.
+type decoder func(c *Client, response CommandResponse) (any, error)
+
+// cmdSpec registers one appchain command family: the fmt.Sprintf format
+// string used to build the command (see the Cmd_* vars) and the decoder
+// for its response. Adding a new command means adding one entry here and
+// one typed method below, instead of scattering fmt.Sprintf/type-assert
+// pairs through callers.
+type cmdSpec struct {
+	format string
+	decode decoder
+}
+
+var commandTable = map[string]cmdSpec{
+	"networks.register":           {Cmd_networks_register, decodeTX},
+	"networks.getNetwork":         {Cmd_networks_getNetwork, decodeNetwork},
+	"nodes.register":              {Cmd_nodes_register, decodeTX},
+	"nodes.getNode":               {Cmd_nodes_getNode, decodeNode},
+	"pki.getDocument":             {Cmd_pki_getDocucment, decodeBytes},
+	"pki.setDocument":             {Cmd_pki_setDocument, decodeTX},
+	"pki.getGenesisEpoch":         {Cmd_pki_getGenesisEpoch, decodeUint},
+	"pki.getMixDescriptor":        {Cmd_pki_getMixDescriptor, decodeBytes},
+	"pki.getMixDescriptorByIndex": {Cmd_pki_getMixDescriptorByIndex, decodeBytes},
+	"pki.getMixDescriptorCounter": {Cmd_pki_getMixDescriptorCounter, decodeUint},
+	"pki.setMixDescriptor":        {Cmd_pki_setMixDescriptor, decodeTX},
+}
+
+func decodeTX(c *Client, response CommandResponse) (any, error) {
+	return response.TX, nil
+}
+
+func decodeBytes(c *Client, response CommandResponse) (any, error) {
+	return c.bridge.GetDataBytes(response)
+}
+
+func decodeUint(c *Client, response CommandResponse) (any, error) {
+	return c.bridge.GetDataUInt(response)
+}
+
+func decodeNetwork(c *Client, response CommandResponse) (any, error) {
+	var network Network
+	if err := c.bridge.DataUnmarshal(response, &network); err != nil {
+		return Network{}, err
+	}
+	return network, nil
+}
+
+func decodeNode(c *Client, response CommandResponse) (any, error) {
+	var node Node
+	if err := c.bridge.DataUnmarshal(response, &node); err != nil {
+		return Node{}, err
+	}
+	return node, nil
+}
+
+// call looks up the command family, formats the command string with args,
+// sends it with payload through the bridge, and decodes the response.
+func (c *Client) call(ctx context.Context, family string, payload []byte, args ...any) (any, error) {
+	spec, ok := commandTable[family]
+	if !ok {
+		return nil, fmt.Errorf("chainbridge: unknown command family %q", family)
+	}
+
+	command := spec.format
+	if len(args) > 0 {
+		command = fmt.Sprintf(spec.format, args...)
+	}
+
+	response, err := c.bridge.CommandContext(ctx, command, payload)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+
+	return spec.decode(c, response)
+}
+
+// RegisterNetwork registers a new network and returns the submitting tx hash.
+func (c *Client) RegisterNetwork(ctx context.Context, network Network) (tx string, err error) {
+	v, err := c.call(ctx, "networks.register", network.Parameters, network.Identifier)
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// GetNetwork retrieves a previously registered network by identifier.
+func (c *Client) GetNetwork(ctx context.Context, identifier string) (Network, error) {
+	v, err := c.call(ctx, "networks.getNetwork", nil, identifier)
+	if err != nil {
+		return Network{}, err
+	}
+	return v.(Network), nil
+}
+
+// RegisterNode registers a new node and returns the submitting tx hash.
+func (c *Client) RegisterNode(ctx context.Context, node Node) (tx string, err error) {
+	v, err := c.call(ctx, "nodes.register", node.IdentityKey,
+		node.Identifier, Bool2int(node.IsGatewayNode), Bool2int(node.IsServiceNode))
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// GetNode retrieves a previously registered node by identifier.
+func (c *Client) GetNode(ctx context.Context, identifier string) (Node, error) {
+	v, err := c.call(ctx, "nodes.getNode", nil, identifier)
+	if err != nil {
+		return Node{}, err
+	}
+	return v.(Node), nil
+}
+
+// GetGenesisEpoch returns the appchain's genesis epoch.
+func (c *Client) GetGenesisEpoch(ctx context.Context) (uint64, error) {
+	v, err := c.call(ctx, "pki.getGenesisEpoch", nil)
+	if err != nil {
+		return 0, err
+	}
+	return v.(uint64), nil
+}
+
+// GetDocument retrieves the finalized consensus document for an epoch.
+func (c *Client) GetDocument(ctx context.Context, epoch uint64) ([]byte, error) {
+	v, err := c.call(ctx, "pki.getDocument", nil, epoch)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]byte), nil
+}
+
+// SetDocument stores the consensus document for an epoch and returns the
+// submitting tx hash.
+func (c *Client) SetDocument(ctx context.Context, epoch uint64, data []byte) (tx string, err error) {
+	v, err := c.call(ctx, "pki.setDocument", data, epoch)
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// GetMixDescriptorCounter returns the number of mix descriptors posted for
+// an epoch so far.
+func (c *Client) GetMixDescriptorCounter(ctx context.Context, epoch uint64) (uint64, error) {
+	v, err := c.call(ctx, "pki.getMixDescriptorCounter", nil, epoch)
+	if err != nil {
+		return 0, err
+	}
+	return v.(uint64), nil
+}
+
+// GetMixDescriptor retrieves a node's mix descriptor for an epoch.
+func (c *Client) GetMixDescriptor(ctx context.Context, epoch uint64, identifier string) ([]byte, error) {
+	v, err := c.call(ctx, "pki.getMixDescriptor", nil, epoch, identifier)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]byte), nil
+}
+
+// GetMixDescriptorByIndex retrieves a mix descriptor by its posting order
+// within an epoch, for callers walking the descriptor counter directly.
+func (c *Client) GetMixDescriptorByIndex(ctx context.Context, epoch, index uint64) ([]byte, error) {
+	v, err := c.call(ctx, "pki.getMixDescriptorByIndex", nil, epoch, index)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]byte), nil
+}
+
+// SetMixDescriptor stores a node's mix descriptor for an epoch and returns
+// the submitting tx hash.
+func (c *Client) SetMixDescriptor(ctx context.Context, epoch uint64, identifier string, data []byte) (tx string, err error) {
+	v, err := c.call(ctx, "pki.setMixDescriptor", data, epoch, identifier)
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
@@ -0,0 +1,162 @@
+package chainbridge
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/0KnowledgeNetwork/appchain-agent/clients/go/chainbridge/log"
+)
+
+// Codec marshals and unmarshals the bridge's wire types (CommandRequest,
+// CommandResponse, Network, Node) for one wire format. Selecting a codec
+// also selects the --socket-format argument passed to a launched agent, so
+// both sides agree on the format (see SetCodec).
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	Name() string
+
+	// DecodeBytes extracts a []byte result from a decoded CommandResponse.Data
+	// value. How that value is represented is codec-specific (e.g. a CBOR
+	// tag vs. raw bytes), so callers go through the codec instead of
+	// unwrapping it themselves.
+	DecodeBytes(data any) ([]byte, error)
+
+	// DecodeUint and DecodeBool extract, respectively, a uint64 or bool
+	// result from a decoded CommandResponse.Data value. As with
+	// DecodeBytes, the representation is codec-specific (e.g. CBOR
+	// represents a uint64 as a decimal string to avoid float-precision
+	// loss, while proto's Data is always raw bytes), so callers go
+	// through the codec rather than type-asserting Data directly.
+	DecodeUint(data any) (uint64, error)
+	DecodeBool(data any) (bool, error)
+}
+
+// CBORCodec is the default wire codec, matching the agent's
+// --socket-format cbor.
+type CBORCodec struct{}
+
+func (CBORCodec) Name() string { return "cbor" }
+
+func (CBORCodec) Marshal(v any) ([]byte, error) {
+	return cbor.Marshal(v)
+}
+
+func (CBORCodec) Unmarshal(data []byte, v any) error {
+	return cbor.Unmarshal(data, v)
+}
+
+func (CBORCodec) DecodeBytes(data any) ([]byte, error) {
+	tag, ok := data.(cbor.Tag)
+	if !ok {
+		return nil, fmt.Errorf("unexpected data type: %T, expected cbor.Tag", data)
+	}
+
+	bytes, ok := tag.Content.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected tag content type: %T, number: %d, expected []byte", tag.Content, tag.Number)
+	}
+
+	return bytes, nil
+}
+
+func (CBORCodec) DecodeUint(data any) (uint64, error) {
+	str, ok := data.(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected data type: %T, expected string", data)
+	}
+
+	num, err := strconv.ParseUint(str, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse uint64: %w", err)
+	}
+
+	return num, nil
+}
+
+func (CBORCodec) DecodeBool(data any) (bool, error) {
+	b, ok := data.(bool)
+	if !ok {
+		return false, fmt.Errorf("unexpected data type: %T, expected bool", data)
+	}
+
+	return b, nil
+}
+
+// SetCodec selects the wire codec used to encode requests and decode
+// responses, and, if ChainBridge was constructed with a command to launch,
+// updates its --socket-format argument to match. Call before Start.
+func (c *ChainBridge) SetCodec(codec Codec) {
+	c.codec = codec
+
+	if c.getCmd() == nil {
+		return
+	}
+	c.cmdArgs = setSocketFormatArg(c.cmdArgs, codec.Name())
+	c.setCmd(exec.Command(c.cmdPath, c.cmdArgs...))
+}
+
+// setSocketFormatArg replaces an existing --socket-format value in args, or
+// appends the flag if it isn't present.
+func setSocketFormatArg(args []string, format string) []string {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == "--socket-format" {
+			args[i+1] = format
+			return args
+		}
+	}
+	return append(args, "--socket-format", format)
+}
+
+// negotiateID is the reserved CommandRequest.ID used for the connect-time
+// wire format handshake, distinct from the per-command IDs issued starting
+// at 1 by CommandContext.
+const negotiateID = 0
+
+// negotiateWireFormat announces the active codec to the agent right after
+// connecting and waits for its acknowledgement, so a codec mismatch between
+// client and agent produces one clear error here instead of a stream of
+// garbled unmarshal failures on every subsequent command.
+//
+// An agent predating this handshake won't recognize the "negotiate
+// socketFormat" command and won't reply, so a missing ack is treated as
+// "legacy agent, proceed" rather than a connect failure: only an explicit
+// mismatch (an ack naming a different format, or a reply that can't be
+// decoded with the client's codec) aborts the connection.
+func (c *ChainBridge) negotiateWireFormat() error {
+	req := CommandRequest{Command: "negotiate socketFormat", Payload: []byte(c.codec.Name()), ID: negotiateID}
+
+	reqData, err := c.codec.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("wire format negotiation: marshal: %w", err)
+	}
+
+	// drain any stale reply left over from a previous connection attempt
+	select {
+	case <-c.negotiateChan:
+	default:
+	}
+
+	if err := c.writeFrame(reqData); err != nil {
+		return fmt.Errorf("wire format negotiation: write: %w", err)
+	}
+
+	select {
+	case response := <-c.negotiateChan:
+		agentFormat, err := c.codec.DecodeBytes(response.Data)
+		if err != nil {
+			return fmt.Errorf("wire format negotiation: decode ack (likely a codec mismatch): %w", err)
+		}
+		if string(agentFormat) != c.codec.Name() {
+			return fmt.Errorf("wire format negotiation: agent is using %q, client is using %q", agentFormat, c.codec.Name())
+		}
+		return nil
+	case <-time.After(c.negotiateTimeout):
+		c.logEvent(log.LevelDebug, "connect", log.F("negotiate", "no ack from agent, assuming legacy agent"))
+		return nil
+	}
+}
@@ -0,0 +1,68 @@
+package chainbridge
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// frameHeaderSize is the width of the length prefix in front of every
+// marshaled message on the wire: the nbio/Unix-socket connection delivers an
+// arbitrary split of bytes per read, so messages need an explicit length to
+// be reassembled rather than relying on one read per message.
+const frameHeaderSize = 4
+
+// defaultMaxFrameSize bounds how large a single frame's payload may be,
+// guarding against a corrupt or hostile length prefix causing an unbounded
+// read buffer allocation.
+const defaultMaxFrameSize = 64 * 1024 * 1024
+
+// writeFrame writes payload prefixed with its length as a 4-byte big-endian
+// uint32. Command/CommandContext/CommandStream can all call this
+// concurrently from different goroutines (responses are demultiplexed by
+// request ID precisely so callers don't have to serialize on their own), so
+// the header and payload are assembled into one buffer and written with a
+// single call under writeMu: two interleaved writers would otherwise desync
+// the framed stream for every frame after.
+func (c *ChainBridge) writeFrame(payload []byte) error {
+	if len(payload) > c.maxFrameSize {
+		return fmt.Errorf("frame of %d bytes exceeds maxFrameSize %d", len(payload), c.maxFrameSize)
+	}
+
+	frame := make([]byte, frameHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame[:frameHeaderSize], uint32(len(payload)))
+	copy(frame[frameHeaderSize:], payload)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// popFrame extracts the first complete frame from the front of buf, if any.
+// It returns ok=false (with no error) when buf doesn't yet hold a complete
+// frame; the caller should wait for more data and try again. An error
+// indicates a length prefix larger than maxFrameSize, which most likely
+// means the stream is desynchronized and can't be recovered.
+func popFrame(buf []byte, maxFrameSize int) (frame []byte, ok bool, err error) {
+	if len(buf) < frameHeaderSize {
+		return nil, false, nil
+	}
+
+	size := binary.BigEndian.Uint32(buf[:frameHeaderSize])
+	if int(size) > maxFrameSize {
+		return nil, false, fmt.Errorf("frame of %d bytes exceeds maxFrameSize %d", size, maxFrameSize)
+	}
+
+	if len(buf) < frameHeaderSize+int(size) {
+		return nil, false, nil
+	}
+
+	return buf[frameHeaderSize : frameHeaderSize+int(size)], true, nil
+}
+
+// SetMaxFrameSize overrides the default maximum frame payload size. Call
+// before Start.
+func (c *ChainBridge) SetMaxFrameSize(n int) {
+	c.maxFrameSize = n
+}
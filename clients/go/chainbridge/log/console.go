@@ -0,0 +1,28 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ConsoleSink writes events as text lines, sending LevelError (and above)
+// to Stderr and everything else to Stdout.
+type ConsoleSink struct {
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// NewConsoleSink returns a ConsoleSink writing to os.Stdout/os.Stderr.
+func NewConsoleSink() *ConsoleSink {
+	return &ConsoleSink{Stdout: os.Stdout, Stderr: os.Stderr}
+}
+
+func (s *ConsoleSink) Log(level Level, msg string, fields ...Field) {
+	w := s.Stdout
+	if level >= LevelError {
+		w = s.Stderr
+	}
+	fmt.Fprintf(w, "%s [%s] %s%s\n", time.Now().Format(time.RFC3339), level, msg, formatFields(fields))
+}
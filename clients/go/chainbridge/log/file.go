@@ -0,0 +1,146 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileSink writes events as text lines to a file, rotating it when it
+// grows past maxSize and pruning rotated backups by count (maxBackups)
+// and age (maxAge).
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink opens (creating if necessary) a rotating file sink at path.
+// maxSize is in bytes; zero disables size-based rotation. maxBackups is
+// the number of rotated files to keep; zero keeps all of them. maxAge
+// prunes rotated files older than it; zero disables age-based pruning.
+func NewFileSink(path string, maxSize int64, maxBackups int, maxAge time.Duration) (*FileSink, error) {
+	s := &FileSink{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		maxAge:     maxAge,
+	}
+	if err := s.openExisting(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) openExisting() error {
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("chainbridge/log: open %s: %w", s.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("chainbridge/log: stat %s: %w", s.path, err)
+	}
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+func (s *FileSink) Log(level Level, msg string, fields ...Field) {
+	line := fmt.Sprintf("%s [%s] %s%s\n", time.Now().Format(time.RFC3339), level, msg, formatFields(fields))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxSize > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "chainbridge/log: rotate %s: %v\n", s.path, err)
+		}
+	}
+
+	n, err := s.file.WriteString(line)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "chainbridge/log: write %s: %v\n", s.path, err)
+		return
+	}
+	s.size += int64(n)
+}
+
+// rotate closes the current file, renames it with a timestamp suffix,
+// reopens a fresh file at path, and prunes old backups. Caller must hold s.mu.
+func (s *FileSink) rotate() error {
+	if s.file != nil {
+		s.file.Close()
+	}
+
+	backup := fmt.Sprintf("%s.%s", s.path, time.Now().Format("20060102T150405.000"))
+	if err := os.Rename(s.path, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := s.openExisting(); err != nil {
+		return err
+	}
+
+	s.pruneBackups()
+	return nil
+}
+
+func (s *FileSink) pruneBackups() {
+	dir := filepath.Dir(s.path)
+	base := filepath.Base(s.path)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	var backups []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, name))
+	}
+	sort.Strings(backups) // timestamp suffix sorts chronologically
+
+	if s.maxAge > 0 {
+		cutoff := time.Now().Add(-s.maxAge)
+		kept := backups[:0]
+		for _, p := range backups {
+			if info, err := os.Stat(p); err == nil && info.ModTime().Before(cutoff) {
+				os.Remove(p)
+				continue
+			}
+			kept = append(kept, p)
+		}
+		backups = kept
+	}
+
+	if s.maxBackups > 0 && len(backups) > s.maxBackups {
+		for _, p := range backups[:len(backups)-s.maxBackups] {
+			os.Remove(p)
+		}
+	}
+}
+
+// Close flushes and closes the underlying file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
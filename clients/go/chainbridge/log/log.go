@@ -0,0 +1,89 @@
+// Package log provides pluggable, structured logging sinks for chainbridge.
+// A Sink receives leveled events with key/value fields; sinks can be
+// composed with MultiSink to fan out to several destinations at once (e.g.
+// console + rotating file + a remote collector).
+package log
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is the severity of a logged event.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// Field is a structured key/value pair attached to a logged event.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// F builds a Field. It's a constructor shorthand, e.g. log.F("reqID", 7).
+func F(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Sink is a logging destination. Implementations must be safe for
+// concurrent use, since chainbridge logs from multiple goroutines.
+type Sink interface {
+	Log(level Level, msg string, fields ...Field)
+}
+
+// MultiSink fans a single Log call out to every sink it contains.
+type MultiSink []Sink
+
+func (m MultiSink) Log(level Level, msg string, fields ...Field) {
+	for _, sink := range m {
+		sink.Log(level, msg, fields...)
+	}
+}
+
+// Add returns a Sink that logs to both s and the given sink. If s is already
+// a MultiSink, add is appended to it rather than nesting s; add itself is
+// appended as-is, even if it is a MultiSink.
+func Add(s Sink, add Sink) Sink {
+	if s == nil {
+		return add
+	}
+	if ms, ok := s.(MultiSink); ok {
+		return append(ms, add)
+	}
+	return MultiSink{s, add}
+}
+
+// formatFields renders fields as " key=value key2=value2" for text sinks.
+func formatFields(fields []Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, f := range fields {
+		b.WriteByte(' ')
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		fmt.Fprintf(&b, "%v", f.Value)
+	}
+	return b.String()
+}
@@ -0,0 +1,63 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// NetworkSink POSTs each event as a JSON line to an HTTP endpoint. Log
+// calls return immediately; delivery happens on a background goroutine, so
+// a slow or unreachable collector never blocks the caller.
+type NetworkSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewNetworkSink returns a NetworkSink posting to url with a default
+// 5-second-timeout HTTP client.
+func NewNetworkSink(url string) *NetworkSink {
+	return &NetworkSink{
+		URL:    url,
+		Client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+type networkEvent struct {
+	Time   string         `json:"time"`
+	Level  string         `json:"level"`
+	Msg    string         `json:"msg"`
+	Fields map[string]any `json:"fields,omitempty"`
+}
+
+func (s *NetworkSink) Log(level Level, msg string, fields ...Field) {
+	fieldMap := make(map[string]any, len(fields))
+	for _, f := range fields {
+		fieldMap[f.Key] = f.Value
+	}
+
+	event := networkEvent{
+		Time:   time.Now().Format(time.RFC3339Nano),
+		Level:  level.String(),
+		Msg:    msg,
+		Fields: fieldMap,
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "chainbridge/log: marshal event: %v\n", err)
+		return
+	}
+
+	go func() {
+		resp, err := s.Client.Post(s.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "chainbridge/log: post event: %v\n", err)
+			return
+		}
+		resp.Body.Close()
+	}()
+}
@@ -0,0 +1,93 @@
+package chainbridge
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/0KnowledgeNetwork/appchain-agent/clients/go/chainbridge/log"
+)
+
+// errorHandlerSink adapts a legacy func(error) handler into a log.Sink,
+// forwarding only LevelError events. It prefers the "err" field's original
+// error value over re-wrapping the formatted message.
+type errorHandlerSink struct {
+	handler func(error)
+}
+
+func (s errorHandlerSink) Log(level log.Level, msg string, fields ...log.Field) {
+	if level < log.LevelError {
+		return
+	}
+	for _, f := range fields {
+		if f.Key == "err" {
+			if err, ok := f.Value.(error); ok && err != nil {
+				s.handler(err)
+				return
+			}
+		}
+	}
+	s.handler(errors.New(msg))
+}
+
+// logHandlerSink adapts a legacy func(string) handler into a log.Sink,
+// forwarding everything below LevelError as a plain formatted string.
+type logHandlerSink struct {
+	handler func(string)
+}
+
+func (s logHandlerSink) Log(level log.Level, msg string, fields ...log.Field) {
+	if level >= log.LevelError {
+		return
+	}
+	line := msg
+	for _, f := range fields {
+		if f.Key == "component" || f.Key == "event" {
+			continue
+		}
+		line += fmt.Sprintf(" %s=%v", f.Key, f.Value)
+	}
+	s.handler(line)
+}
+
+// defaultSink is used when no sink has been configured via SetSink,
+// SetErrorHandler, or SetLogHandler.
+var defaultSink log.Sink = log.NewConsoleSink()
+
+// SetSink sets the destination for chainbridge's structured logs, replacing
+// any sink installed by a previous SetSink/SetErrorHandler/SetLogHandler
+// call. Every event carries component=chainbridge and an event name (e.g.
+// connect, reconnect, send, recv, timeout, close).
+func (c *ChainBridge) SetSink(sink log.Sink) {
+	c.sink = sink
+}
+
+// SetErrorHandler sets a custom error handler to be called when an error
+// occurs. It's a thin shim over SetSink for callers that don't need
+// structured fields: internally it adds a sink that forwards LevelError
+// events as plain errors.
+func (c *ChainBridge) SetErrorHandler(handler func(error)) {
+	c.sink = log.Add(c.sink, errorHandlerSink{handler})
+}
+
+// SetLogHandler sets a custom log handler to be called for non-error logs.
+// It's a thin shim over SetSink for callers that don't need structured
+// fields: internally it adds a sink that forwards everything below
+// LevelError as a plain formatted string.
+func (c *ChainBridge) SetLogHandler(handler func(string)) {
+	c.sink = log.Add(c.sink, logHandlerSink{handler})
+}
+
+// logEvent emits a structured event through the configured sink (or the
+// default console sink if none was set), tagging it with component=chainbridge.
+func (c *ChainBridge) logEvent(level log.Level, event string, fields ...log.Field) {
+	sink := c.sink
+	if sink == nil {
+		sink = defaultSink
+	}
+	all := append([]log.Field{log.F("component", "chainbridge"), log.F("event", event)}, fields...)
+	sink.Log(level, event, all...)
+}
+
+func (c *ChainBridge) handleError(err error) {
+	c.logEvent(log.LevelError, "error", log.F("err", err))
+}
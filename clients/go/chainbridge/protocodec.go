@@ -0,0 +1,310 @@
+package chainbridge
+
+import (
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ProtoCodec is an alternative wire codec, matching the agent's
+// --socket-format proto. Its wire layout is defined by proto/chainbridge.proto;
+// since only these four flat message shapes ever cross the wire, they're
+// encoded/decoded directly against protowire below rather than through full
+// protoc-generated bindings.
+type ProtoCodec struct{}
+
+func (ProtoCodec) Name() string { return "proto" }
+
+func (ProtoCodec) Marshal(v any) ([]byte, error) {
+	switch m := v.(type) {
+	case CommandRequest:
+		return marshalCommandRequest(m), nil
+	case *CommandRequest:
+		return marshalCommandRequest(*m), nil
+	case CommandResponse:
+		return marshalCommandResponse(m), nil
+	case *CommandResponse:
+		return marshalCommandResponse(*m), nil
+	case Network:
+		return marshalNetwork(m), nil
+	case *Network:
+		return marshalNetwork(*m), nil
+	case Node:
+		return marshalNode(m), nil
+	case *Node:
+		return marshalNode(*m), nil
+	default:
+		return nil, fmt.Errorf("chainbridge: ProtoCodec cannot marshal %T", v)
+	}
+}
+
+func (ProtoCodec) Unmarshal(data []byte, v any) error {
+	switch m := v.(type) {
+	case *CommandRequest:
+		return unmarshalCommandRequest(data, m)
+	case *CommandResponse:
+		return unmarshalCommandResponse(data, m)
+	case *Network:
+		return unmarshalNetwork(data, m)
+	case *Node:
+		return unmarshalNode(data, m)
+	default:
+		return fmt.Errorf("chainbridge: ProtoCodec cannot unmarshal into %T", v)
+	}
+}
+
+// DecodeBytes returns data as-is: the proto wire format stores a bytes
+// field as a plain []byte, with none of CBOR's tag wrapping to unwind.
+func (ProtoCodec) DecodeBytes(data any) ([]byte, error) {
+	b, ok := data.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected data type: %T, expected []byte", data)
+	}
+	return b, nil
+}
+
+// DecodeUint and DecodeBool parse a proto Data field (always raw bytes, per
+// unmarshalCommandResponse) as text: DecodeUint as a decimal string, the
+// same representation CBOR uses for a uint64, and DecodeBool as a
+// strconv.ParseBool string (e.g. "true"/"false"), whereas CBOR carries a
+// bool natively.
+func (ProtoCodec) DecodeUint(data any) (uint64, error) {
+	b, ok := data.([]byte)
+	if !ok {
+		return 0, fmt.Errorf("unexpected data type: %T, expected []byte", data)
+	}
+
+	num, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse uint64: %w", err)
+	}
+
+	return num, nil
+}
+
+func (ProtoCodec) DecodeBool(data any) (bool, error) {
+	b, ok := data.([]byte)
+	if !ok {
+		return false, fmt.Errorf("unexpected data type: %T, expected []byte", data)
+	}
+
+	v, err := strconv.ParseBool(string(b))
+	if err != nil {
+		return false, fmt.Errorf("failed to parse bool: %w", err)
+	}
+
+	return v, nil
+}
+
+func marshalCommandRequest(m CommandRequest) []byte {
+	var b []byte
+	if m.Command != "" {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, m.Command)
+	}
+	if len(m.Payload) > 0 {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, m.Payload)
+	}
+	if m.ID != 0 {
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(m.ID))
+	}
+	return b
+}
+
+func unmarshalCommandRequest(data []byte, m *CommandRequest) error {
+	*m = CommandRequest{}
+	return consumeFields(data, func(num protowire.Number, typ protowire.Type, data []byte) (int, error) {
+		switch num {
+		case 1:
+			v, n := protowire.ConsumeString(data)
+			m.Command = v
+			return n, protowire.ParseError(n)
+		case 2:
+			v, n := protowire.ConsumeBytes(data)
+			m.Payload = append([]byte(nil), v...)
+			return n, protowire.ParseError(n)
+		case 3:
+			v, n := protowire.ConsumeVarint(data)
+			m.ID = int(v)
+			return n, protowire.ParseError(n)
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, data)
+			return n, protowire.ParseError(n)
+		}
+	})
+}
+
+func marshalCommandResponse(m CommandResponse) []byte {
+	var b []byte
+	if m.Status != "" {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, m.Status)
+	}
+	if data, ok := m.Data.([]byte); ok && len(data) > 0 {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, data)
+	}
+	if m.Error != "" {
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendString(b, m.Error)
+	}
+	if m.ID != 0 {
+		b = protowire.AppendTag(b, 4, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(m.ID))
+	}
+	if m.TX != "" {
+		b = protowire.AppendTag(b, 5, protowire.BytesType)
+		b = protowire.AppendString(b, m.TX)
+	}
+	if m.Final {
+		b = protowire.AppendTag(b, 6, protowire.VarintType)
+		b = protowire.AppendVarint(b, 1)
+	}
+	return b
+}
+
+func unmarshalCommandResponse(data []byte, m *CommandResponse) error {
+	*m = CommandResponse{}
+	return consumeFields(data, func(num protowire.Number, typ protowire.Type, data []byte) (int, error) {
+		switch num {
+		case 1:
+			v, n := protowire.ConsumeString(data)
+			m.Status = v
+			return n, protowire.ParseError(n)
+		case 2:
+			v, n := protowire.ConsumeBytes(data)
+			m.Data = append([]byte(nil), v...)
+			return n, protowire.ParseError(n)
+		case 3:
+			v, n := protowire.ConsumeString(data)
+			m.Error = v
+			return n, protowire.ParseError(n)
+		case 4:
+			v, n := protowire.ConsumeVarint(data)
+			m.ID = int(v)
+			return n, protowire.ParseError(n)
+		case 5:
+			v, n := protowire.ConsumeString(data)
+			m.TX = v
+			return n, protowire.ParseError(n)
+		case 6:
+			v, n := protowire.ConsumeVarint(data)
+			m.Final = v != 0
+			return n, protowire.ParseError(n)
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, data)
+			return n, protowire.ParseError(n)
+		}
+	})
+}
+
+func marshalNetwork(m Network) []byte {
+	var b []byte
+	if m.Identifier != "" {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, m.Identifier)
+	}
+	if len(m.Parameters) > 0 {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, m.Parameters)
+	}
+	return b
+}
+
+func unmarshalNetwork(data []byte, m *Network) error {
+	*m = Network{}
+	return consumeFields(data, func(num protowire.Number, typ protowire.Type, data []byte) (int, error) {
+		switch num {
+		case 1:
+			v, n := protowire.ConsumeString(data)
+			m.Identifier = v
+			return n, protowire.ParseError(n)
+		case 2:
+			v, n := protowire.ConsumeBytes(data)
+			m.Parameters = append([]byte(nil), v...)
+			return n, protowire.ParseError(n)
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, data)
+			return n, protowire.ParseError(n)
+		}
+	})
+}
+
+func marshalNode(m Node) []byte {
+	var b []byte
+	if m.Administrator != "" {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, m.Administrator)
+	}
+	if m.Identifier != "" {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendString(b, m.Identifier)
+	}
+	if m.IsGatewayNode {
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, 1)
+	}
+	if m.IsServiceNode {
+		b = protowire.AppendTag(b, 4, protowire.VarintType)
+		b = protowire.AppendVarint(b, 1)
+	}
+	if len(m.IdentityKey) > 0 {
+		b = protowire.AppendTag(b, 5, protowire.BytesType)
+		b = protowire.AppendBytes(b, m.IdentityKey)
+	}
+	return b
+}
+
+func unmarshalNode(data []byte, m *Node) error {
+	*m = Node{}
+	return consumeFields(data, func(num protowire.Number, typ protowire.Type, data []byte) (int, error) {
+		switch num {
+		case 1:
+			v, n := protowire.ConsumeString(data)
+			m.Administrator = v
+			return n, protowire.ParseError(n)
+		case 2:
+			v, n := protowire.ConsumeString(data)
+			m.Identifier = v
+			return n, protowire.ParseError(n)
+		case 3:
+			v, n := protowire.ConsumeVarint(data)
+			m.IsGatewayNode = v != 0
+			return n, protowire.ParseError(n)
+		case 4:
+			v, n := protowire.ConsumeVarint(data)
+			m.IsServiceNode = v != 0
+			return n, protowire.ParseError(n)
+		case 5:
+			v, n := protowire.ConsumeBytes(data)
+			m.IdentityKey = append([]byte(nil), v...)
+			return n, protowire.ParseError(n)
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, data)
+			return n, protowire.ParseError(n)
+		}
+	})
+}
+
+// consumeFields walks a proto wire-format message, calling handle for each
+// field's tag with the remaining data positioned right after that tag; it
+// returns the number of bytes handle consumed from that position.
+func consumeFields(data []byte, handle func(num protowire.Number, typ protowire.Type, data []byte) (int, error)) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		consumed, err := handle(num, typ, data)
+		if err != nil {
+			return err
+		}
+		data = data[consumed:]
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package chainbridge
+
+import (
+	"os/exec"
+	"testing"
+)
+
+// TestReapChildrenIgnoresUnrelatedChildren verifies reapChildren only waits
+// on the agent's own pid, so it doesn't steal reaping of an unrelated child
+// of the host process.
+func TestReapChildrenIgnoresUnrelatedChildren(t *testing.T) {
+	other := exec.Command("sh", "-c", "exit 0")
+	if err := other.Start(); err != nil {
+		t.Fatalf("failed to start unrelated child: %v", err)
+	}
+
+	agent := exec.Command("sh", "-c", "sleep 5")
+	if err := agent.Start(); err != nil {
+		t.Fatalf("failed to start agent stand-in: %v", err)
+	}
+	defer agent.Process.Kill()
+
+	bridge := &ChainBridge{cmd: agent}
+	bridge.reapChildren()
+
+	if err := other.Wait(); err != nil {
+		t.Fatalf("unrelated child should still be reapable by the host, got: %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package chainbridge
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/0KnowledgeNetwork/appchain-agent/clients/go/chainbridge/log"
+)
+
+// RestartPolicy controls whether and how ChainBridge re-execs its launched
+// agent process after the agent exits unexpectedly. It only applies when
+// ChainBridge was constructed with a command to launch, not when connecting
+// to an existing socket file.
+type RestartPolicy struct {
+	// MaxRetries is the maximum number of consecutive restarts attempted
+	// before giving up and reporting an error via the error handler.
+	MaxRetries int
+	// Backoff is the delay before each restart attempt.
+	Backoff time.Duration
+	// ResetAfter is how long the agent must stay up before the retry
+	// counter resets to zero.
+	ResetAfter time.Duration
+}
+
+// SetExitHandler sets a hook called with the launched agent's pid and exit
+// status whenever the supervisor reaps it, whether or not a restart follows.
+func (c *ChainBridge) SetExitHandler(handler func(pid int, ws syscall.WaitStatus)) {
+	c.exitHandler = handler
+}
+
+// SetRestartPolicy enables automatic re-exec of the launched agent command
+// when it exits. This is distinct from the socket-level reconnect loop in
+// onClose, which only handles the case where the agent process is still
+// alive but the connection dropped.
+func (c *ChainBridge) SetRestartPolicy(policy RestartPolicy) {
+	c.restartPolicy = &policy
+}
+
+// superviseChild installs a SIGCHLD handler and reaps the launched agent
+// process (modeled on the classic Wait4(-1, &ws, WNOHANG, nil) reap loop)
+// until Stop closes c.supervisorStop.
+func (c *ChainBridge) superviseChild() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGCHLD)
+
+	go func() {
+		defer signal.Stop(sigCh)
+
+		for {
+			select {
+			case <-c.supervisorStop:
+				return
+			case <-sigCh:
+				c.reapChildren()
+			}
+		}
+	}()
+}
+
+// reapChildren waits specifically on the agent's own pid (WNOHANG), so a
+// SIGCHLD delivered for some unrelated child of the host process is left
+// for the host's own handler rather than being silently consumed here.
+func (c *ChainBridge) reapChildren() {
+	cmd := c.getCmd()
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	agentPid := cmd.Process.Pid
+
+	for {
+		var ws syscall.WaitStatus
+		pid, err := syscall.Wait4(agentPid, &ws, syscall.WNOHANG, nil)
+		if err != nil {
+			if err == syscall.EINTR {
+				continue
+			}
+			// ECHILD: agentPid has already been reaped elsewhere
+			return
+		}
+		if pid <= 0 {
+			// pid == 0: agent still running
+			return
+		}
+
+		if c.exitHandler != nil {
+			c.exitHandler(pid, ws)
+		}
+
+		c.stoppingMu.Lock()
+		stopping := c.stopping
+		c.stoppingMu.Unlock()
+		if stopping {
+			return
+		}
+
+		c.restartChild(ws)
+		return
+	}
+}
+
+// restartChild re-execs the agent command per the configured RestartPolicy,
+// re-reading UNIX_SOCKET_PATH= from its stdout and reattaching the socket
+// connection.
+func (c *ChainBridge) restartChild(ws syscall.WaitStatus) {
+	if c.restartPolicy == nil || c.restartPolicy.MaxRetries == 0 {
+		return
+	}
+
+	c.logEvent(log.LevelWarn, "reconnect", log.F("reason", "agent process exited"), log.F("status", fmt.Sprintf("%v", ws)))
+
+	c.restartMu.Lock()
+	if c.restartCount >= c.restartPolicy.MaxRetries {
+		c.restartMu.Unlock()
+		c.handleError(fmt.Errorf("agent process exited and exceeded %d restart attempts", c.restartPolicy.MaxRetries))
+		return
+	}
+	c.restartCount++
+	generation := c.restartCount
+	c.restartMu.Unlock()
+
+	if c.restartPolicy.Backoff > 0 {
+		time.Sleep(c.restartPolicy.Backoff)
+	}
+
+	c.setCmd(exec.Command(c.cmdPath, c.cmdArgs...))
+	c.setSocketFile("")
+	if err := c.launchCommand(); err != nil {
+		c.handleError(fmt.Errorf("failed to restart agent process: %w", err))
+		return
+	}
+	if err := c.connectToSocket(); err != nil {
+		c.handleError(fmt.Errorf("failed to reconnect after restart: %w", err))
+		return
+	}
+
+	if c.restartPolicy.ResetAfter > 0 {
+		go func() {
+			time.Sleep(c.restartPolicy.ResetAfter)
+			c.restartMu.Lock()
+			if c.restartCount == generation {
+				c.restartCount = 0
+			}
+			c.restartMu.Unlock()
+		}()
+	}
+}
@@ -0,0 +1,82 @@
+package chainbridge
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestHelperProcess is not a real test. It's re-exec'd as the "agent"
+// process by TestChildDeathFailsPendingCommand (the os/exec pattern used to
+// stand up a throwaway subprocess without a separate testdata binary),
+// selected via the GO_WANT_HELPER_PROCESS env var.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	defer os.Exit(0)
+
+	ln, err := net.Listen("unix", os.Getenv("HELPER_SOCKET_PATH"))
+	if err != nil {
+		os.Exit(1)
+	}
+	defer ln.Close()
+
+	fmt.Println("UNIX_SOCKET_PATH=" + os.Getenv("HELPER_SOCKET_PATH"))
+
+	conn, err := ln.Accept()
+	if err != nil {
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	// Hold the connection open without ever replying, so a command sent
+	// through it stays pending until this process is killed.
+	select {}
+}
+
+// TestChildDeathFailsPendingCommand kills the agent process mid-command and
+// asserts the pending CommandContext call gets a prompt error instead of
+// blocking for the full cmdTimeout.
+func TestChildDeathFailsPendingCommand(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "agent.sock")
+
+	bridge := NewChainBridge(os.Args[0], "-test.run=^TestHelperProcess$")
+	bridge.cmd.Env = append(os.Environ(),
+		"GO_WANT_HELPER_PROCESS=1",
+		"HELPER_SOCKET_PATH="+sockPath,
+	)
+	bridge.cmdTimeout = 50 * time.Second
+
+	if err := bridge.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { bridge.Stop() })
+
+	// The socket-level reconnect loop is orthogonal to what's under test
+	// here (failPendingRequests firing before any reconnect is attempted);
+	// disable it so a dead agent doesn't leave a retry loop running.
+	bridge.reconnectMu.Lock()
+	bridge.reconnect = false
+	bridge.reconnectMu.Unlock()
+
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		bridge.cmd.Process.Kill()
+	}()
+
+	start := time.Now()
+	response, err := bridge.CommandContext(context.Background(), "noop", nil)
+	elapsed := time.Since(start)
+
+	if err == nil && response.Error == "" {
+		t.Fatalf("expected a disconnect error after the child was killed, got a clean response")
+	}
+	if elapsed > 5*time.Second {
+		t.Fatalf("CommandContext blocked for %v waiting on a dead child; expected a prompt disconnect error", elapsed)
+	}
+}
@@ -0,0 +1,365 @@
+package chainbridge
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// watchPollInterval is how often a watcher re-checks the appchain for new
+// epochs, mix descriptors, or a finalized document.
+const watchPollInterval = 2 * time.Second
+
+// MixDescriptorEvent is emitted by WatchMixDescriptors for each mix
+// descriptor discovered in the watched epoch, in posting order.
+type MixDescriptorEvent struct {
+	Index uint64
+	ID    string
+	Bytes []byte
+}
+
+// mixDescriptorIdentity decodes just enough of a mix descriptor's CBOR
+// payload to label a MixDescriptorEvent with its node identifier.
+type mixDescriptorIdentity struct {
+	Identifier string `cbor:"identifier"`
+}
+
+// epochWatch, mixDescriptorWatch, and documentWatch each back one "watch
+// set": a single poller goroutine whose result fans out to every
+// subscriber, so that many callers watching the same thing (or the same
+// epoch) only issue one stream of requests to the agent.
+type epochWatch struct {
+	mu          sync.Mutex
+	subscribers []chan uint64
+	known       bool
+	epoch       uint64
+	cancel      context.CancelFunc
+}
+
+// mixDescriptorSubscriber pairs a subscriber channel with the index of the
+// next cached event to deliver to it, so a subscriber whose buffer is
+// momentarily full just falls behind and catches up on a later poll instead
+// of losing the descriptor it missed.
+type mixDescriptorSubscriber struct {
+	ch   chan MixDescriptorEvent
+	next int
+}
+
+type mixDescriptorWatch struct {
+	mu          sync.Mutex
+	events      []MixDescriptorEvent
+	subscribers []*mixDescriptorSubscriber
+	cancel      context.CancelFunc
+}
+
+type documentWatch struct {
+	mu          sync.Mutex
+	subscribers []chan []byte
+	cancel      context.CancelFunc
+}
+
+// WatchEpochs returns a channel that receives the genesis epoch once it
+// becomes known. The genesis epoch is fixed at chain bootstrap and never
+// changes again, so this isn't watching for an advancing value — it's for a
+// caller that starts up before genesis has been recorded and wants to be
+// notified as soon as it is, without hand-rolling its own poll loop. All
+// subscribers share one polling goroutine; a subscriber joining after the
+// epoch is already known receives it immediately. The channel is closed
+// when ctx is done.
+func (c *Client) WatchEpochs(ctx context.Context) (<-chan uint64, error) {
+	c.watchMu.Lock()
+	if c.epochWatch == nil {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		c.epochWatch = &epochWatch{cancel: cancel}
+		go c.pollEpochs(watchCtx, c.epochWatch)
+	}
+	w := c.epochWatch
+
+	ch := make(chan uint64, 1)
+	w.mu.Lock()
+	w.subscribers = append(w.subscribers, ch)
+	if w.known {
+		select {
+		case ch <- w.epoch:
+		default:
+		}
+	}
+	w.mu.Unlock()
+	c.watchMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		c.unsubscribeEpochs(ch)
+	}()
+
+	return ch, nil
+}
+
+func (c *Client) unsubscribeEpochs(ch chan uint64) {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+
+	w := c.epochWatch
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	removed := removeSubscriber(&w.subscribers, ch)
+	empty := len(w.subscribers) == 0
+	w.mu.Unlock()
+	if removed {
+		close(ch)
+	}
+	if empty {
+		w.cancel()
+		c.epochWatch = nil
+	}
+}
+
+func (c *Client) pollEpochs(ctx context.Context, w *epochWatch) {
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if epoch, err := c.GetGenesisEpoch(ctx); err == nil {
+			w.mu.Lock()
+			w.known = true
+			w.epoch = epoch
+			for _, sub := range w.subscribers {
+				select {
+				case sub <- epoch:
+				default:
+				}
+			}
+			w.mu.Unlock()
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// WatchMixDescriptors returns a channel that receives each mix descriptor
+// posted for epoch, in posting order, by tracking the descriptor counter
+// and reading each newly posted slot. Subscribers sharing an epoch share one
+// polling goroutine. The channel is closed when ctx is done.
+func (c *Client) WatchMixDescriptors(ctx context.Context, epoch uint64) (<-chan MixDescriptorEvent, error) {
+	c.watchMu.Lock()
+	if c.mixWatches == nil {
+		c.mixWatches = make(map[uint64]*mixDescriptorWatch)
+	}
+	w, ok := c.mixWatches[epoch]
+	if !ok {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		w = &mixDescriptorWatch{cancel: cancel}
+		c.mixWatches[epoch] = w
+		go c.pollMixDescriptors(watchCtx, epoch, w)
+	}
+
+	ch := make(chan MixDescriptorEvent, 8)
+	sub := &mixDescriptorSubscriber{ch: ch}
+	w.mu.Lock()
+	w.subscribers = append(w.subscribers, sub)
+	w.mu.Unlock()
+	c.watchMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		c.unsubscribeMixDescriptors(epoch, sub)
+	}()
+
+	return ch, nil
+}
+
+func (c *Client) unsubscribeMixDescriptors(epoch uint64, sub *mixDescriptorSubscriber) {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+
+	w, ok := c.mixWatches[epoch]
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	removed := false
+	for i, s := range w.subscribers {
+		if s == sub {
+			w.subscribers = append(w.subscribers[:i], w.subscribers[i+1:]...)
+			removed = true
+			break
+		}
+	}
+	empty := len(w.subscribers) == 0
+	if removed {
+		// close(sub.ch) happens under w.mu, the same lock pollMixDescriptors
+		// holds while it sends, so a send can never race a close.
+		close(sub.ch)
+	}
+	w.mu.Unlock()
+	if empty {
+		w.cancel()
+		delete(c.mixWatches, epoch)
+	}
+}
+
+func (c *Client) pollMixDescriptors(ctx context.Context, epoch uint64, w *mixDescriptorWatch) {
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	var nextIndex uint64
+
+	for {
+		if count, err := c.GetMixDescriptorCounter(ctx, epoch); err == nil {
+			for ; nextIndex < count; nextIndex++ {
+				data, err := c.GetMixDescriptorByIndex(ctx, epoch, nextIndex)
+				if err != nil {
+					break
+				}
+
+				event := MixDescriptorEvent{Index: nextIndex, Bytes: data}
+				var id mixDescriptorIdentity
+				if cbor.Unmarshal(data, &id) == nil {
+					event.ID = id.Identifier
+				}
+
+				w.mu.Lock()
+				w.events = append(w.events, event)
+				c.deliverMixDescriptors(w)
+				w.mu.Unlock()
+			}
+		}
+
+		// deliver on every poll too, so a subscriber that fell behind (or
+		// joined late) catches up even when no new descriptor was posted
+		w.mu.Lock()
+		c.deliverMixDescriptors(w)
+		w.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverMixDescriptors sends each subscriber every cached event it hasn't
+// seen yet, called with w.mu held so delivery can never race a concurrent
+// close from unsubscribeMixDescriptors. A subscriber whose buffer is full
+// just stops advancing for this pass and catches up on the next poll,
+// instead of blocking (and stalling every other subscriber) or dropping the
+// event out of posting order.
+func (c *Client) deliverMixDescriptors(w *mixDescriptorWatch) {
+	for _, sub := range w.subscribers {
+	drain:
+		for sub.next < len(w.events) {
+			select {
+			case sub.ch <- w.events[sub.next]:
+				sub.next++
+			default:
+				break drain
+			}
+		}
+	}
+}
+
+// WatchDocument returns a channel that receives the consensus document for
+// epoch once it's finalized, then is closed. Subscribers sharing an epoch
+// share one polling goroutine.
+func (c *Client) WatchDocument(ctx context.Context, epoch uint64) (<-chan []byte, error) {
+	c.watchMu.Lock()
+	if c.docWatches == nil {
+		c.docWatches = make(map[uint64]*documentWatch)
+	}
+	w, ok := c.docWatches[epoch]
+	if !ok {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		w = &documentWatch{cancel: cancel}
+		c.docWatches[epoch] = w
+		go c.pollDocument(watchCtx, epoch, w)
+	}
+
+	ch := make(chan []byte, 1)
+	w.mu.Lock()
+	w.subscribers = append(w.subscribers, ch)
+	w.mu.Unlock()
+	c.watchMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		c.unsubscribeDocument(epoch, ch)
+	}()
+
+	return ch, nil
+}
+
+func (c *Client) unsubscribeDocument(epoch uint64, ch chan []byte) {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+
+	w, ok := c.docWatches[epoch]
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	removed := removeSubscriber(&w.subscribers, ch)
+	empty := len(w.subscribers) == 0
+	w.mu.Unlock()
+	if removed {
+		close(ch)
+	}
+	if empty {
+		w.cancel()
+		delete(c.docWatches, epoch)
+	}
+}
+
+func (c *Client) pollDocument(ctx context.Context, epoch uint64, w *documentWatch) {
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if data, err := c.GetDocument(ctx, epoch); err == nil && len(data) > 0 {
+			w.mu.Lock()
+			subscribers := w.subscribers
+			w.subscribers = nil
+			w.mu.Unlock()
+
+			for _, sub := range subscribers {
+				select {
+				case sub <- data:
+				default:
+				}
+				close(sub)
+			}
+
+			c.watchMu.Lock()
+			delete(c.docWatches, epoch)
+			c.watchMu.Unlock()
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// removeSubscriber removes ch from *subscribers if present, reporting
+// whether it found (and removed) it.
+func removeSubscriber[T any](subscribers *[]chan T, ch chan T) bool {
+	for i, sub := range *subscribers {
+		if sub == ch {
+			*subscribers = append((*subscribers)[:i], (*subscribers)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}